@@ -0,0 +1,38 @@
+// Copyright 2014 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package graph defines the base types shared by Cayley's quad stores and
+// the readers that feed them.
+package graph
+
+import "fmt"
+
+// Triple is a subject-predicate-object triple, optionally scoped to a named
+// graph via Provenance. The four fields are the raw, unparsed lexical forms
+// produced by a reader (e.g. "<iri>", "_:b0", "\"lit\"@en"); callers that
+// need to tell an IRI from a blank node from a literal must inspect the
+// syntax themselves.
+type Triple struct {
+	Subject    string
+	Predicate  string
+	Object     string
+	Provenance string
+}
+
+func (t *Triple) String() string {
+	if t.Provenance == "" {
+		return fmt.Sprintf("%s -- %s -> %s", t.Subject, t.Predicate, t.Object)
+	}
+	return fmt.Sprintf("%s -- %s -> %s (%s)", t.Subject, t.Predicate, t.Object, t.Provenance)
+}
@@ -0,0 +1,133 @@
+// Copyright 2014 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package iri implements IRI syntax validation (RFC 3987) and syntax-based
+// normalization (RFC 3986 §6), self-contained so that nquads and future
+// Turtle/JSON-LD readers can share it without pulling in a general-purpose
+// URL library.
+package iri
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// disallowed matches the characters an IRI reference may never contain
+// literally (they must be percent-encoded instead), mirroring the
+// IRIREF production used by the N-Triples/N-Quads grammar: control
+// characters, whitespace, and the delimiters '<' '>' '"' '{' '}' '|' '^'
+// '`' '\'.
+var disallowed = regexp.MustCompile("[\\x00-\\x20<>\"{}|^`\\\\]")
+
+// IRI is a parsed IRI reference, split into its RFC 3986 components.
+// Path and Fragment are kept in their raw, percent-encoded lexical form
+// (net/url's EscapedPath/EscapedFragment) rather than decoded, the same
+// as Query already was: decoding a reserved octet like "%2F" would turn
+// it into a literal character indistinguishable from one that was never
+// encoded, changing what the IRI refers to.
+type IRI struct {
+	Scheme   string
+	Userinfo string
+	Host     string
+	Port     string
+	Path     string
+	Query    string
+	Fragment string
+}
+
+// Parse validates s against the IRI grammar and splits it into components.
+// s may be an absolute IRI or a relative reference; use Resolve to turn a
+// relative reference into an absolute IRI against a base.
+func Parse(s string) (*IRI, error) {
+	if loc := disallowed.FindStringIndex(s); loc != nil {
+		return nil, fmt.Errorf("iri: disallowed character at byte %d in %q", loc[0], s)
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, fmt.Errorf("iri: %v", err)
+	}
+	ir := &IRI{
+		Scheme:   u.Scheme,
+		Host:     u.Hostname(),
+		Port:     u.Port(),
+		Path:     u.EscapedPath(),
+		Query:    u.RawQuery,
+		Fragment: u.EscapedFragment(),
+	}
+	if u.User != nil {
+		ir.Userinfo = u.User.String()
+	}
+	return ir, nil
+}
+
+// String reassembles the IRI into its lexical form.
+func (ir *IRI) String() string {
+	return ir.toURL().String()
+}
+
+// Resolve resolves ref against base as RFC 3986 §5 describes, returning
+// the resulting absolute IRI.
+func Resolve(base, ref string) (string, error) {
+	b, err := Parse(base)
+	if err != nil {
+		return "", fmt.Errorf("iri: invalid base: %v", err)
+	}
+	r, err := Parse(ref)
+	if err != nil {
+		return "", fmt.Errorf("iri: invalid reference: %v", err)
+	}
+	bu, ru := b.toURL(), r.toURL()
+	return bu.ResolveReference(ru).String(), nil
+}
+
+// toURL reassembles ir into a net/url.URL, pairing each of Path and
+// Fragment with its decoded form so that url.URL's own escaping logic
+// (EscapedPath/EscapedFragment) reproduces ir.Path/ir.Fragment exactly
+// rather than re-escaping them from scratch.
+func (ir *IRI) toURL() *url.URL {
+	host := ir.Host
+	if ir.Port != "" {
+		host += ":" + ir.Port
+	}
+	u := &url.URL{Scheme: ir.Scheme, Host: host, RawQuery: ir.Query}
+	if ir.Userinfo != "" {
+		// ir.Userinfo is already escaped; decode it so url.Userinfo's own
+		// escaping reproduces it instead of escaping it a second time.
+		name, pass, hasPass := strings.Cut(ir.Userinfo, ":")
+		if n, err := url.PathUnescape(name); err == nil {
+			name = n
+		}
+		if p, err := url.PathUnescape(pass); err == nil {
+			pass = p
+		}
+		if hasPass {
+			u.User = url.UserPassword(name, pass)
+		} else {
+			u.User = url.User(name)
+		}
+	}
+	if p, err := url.PathUnescape(ir.Path); err == nil {
+		u.Path, u.RawPath = p, ir.Path
+	} else {
+		u.Path = ir.Path
+	}
+	if f, err := url.PathUnescape(ir.Fragment); err == nil {
+		u.Fragment, u.RawFragment = f, ir.Fragment
+	} else {
+		u.Fragment = ir.Fragment
+	}
+	return u
+}
@@ -0,0 +1,66 @@
+// Copyright 2014 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iri
+
+import "testing"
+
+func TestParseRejectsIllegalCharacters(t *testing.T) {
+	for _, s := range []string{
+		"http://example.com/a b",
+		"http://example.com/<a>",
+		"http://example.com/a\"b",
+	} {
+		if _, err := Parse(s); err == nil {
+			t.Errorf("Parse(%q): expected an error, got none", s)
+		}
+	}
+}
+
+var normalizeTests = []struct {
+	input, want string
+}{
+	{"HTTP://Example.com/a/./b", "http://example.com/a/b"},
+	{"http://example.com:80/", "http://example.com/"},
+	{"https://example.com:443/x", "https://example.com/x"},
+	{"http://example.com/a/b/../c", "http://example.com/a/c"},
+	{"http://example.com/%7Euser", "http://example.com/~user"},
+	{"http://example.com/%2a", "http://example.com/%2A"},
+	{"http://example.com/%2535", "http://example.com/%2535"},
+	{"http://example.com/a%2Fb/c", "http://example.com/a%2Fb/c"},
+	{"http://example.com/path#a%2Fb", "http://example.com/path#a%2Fb"},
+}
+
+func TestNormalize(t *testing.T) {
+	for _, test := range normalizeTests {
+		got, err := Normalize(test.input)
+		if err != nil {
+			t.Errorf("Normalize(%q): unexpected error: %v", test.input, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("Normalize(%q) = %q, want %q", test.input, got, test.want)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	got, err := Resolve("http://example.com/a/b", "../c")
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if want := "http://example.com/c"; got != want {
+		t.Errorf("Resolve = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2014 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iri
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// defaultPorts lists the schemes whose default port RFC 3986 §6.2.3 says
+// to strip during normalization.
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
+
+// Normalize returns s rewritten to its RFC 3986 §6 syntax-based normal
+// form: lowercase scheme and host, normalized percent-encoding, the
+// scheme's default port removed, and "." / ".." path segments resolved
+// away. Two IRIs that normalize to the same string are guaranteed to be
+// equivalent, though the converse need not hold.
+func Normalize(s string) (string, error) {
+	ir, err := Parse(s)
+	if err != nil {
+		return "", err
+	}
+
+	ir.Scheme = strings.ToLower(ir.Scheme)
+	ir.Host = strings.ToLower(ir.Host)
+	// Userinfo, Path, Query, and Fragment are all in their raw
+	// percent-encoded form (see the IRI doc comment), so each gets
+	// exactly one normalizePercentEncoding pass. removeDotSegments runs
+	// on that same raw form, rather than after decoding, so an encoded
+	// delimiter like "%2F" is never mistaken for a "/" path separator.
+	ir.Userinfo = normalizePercentEncoding(ir.Userinfo)
+	ir.Path = removeDotSegments(normalizePercentEncoding(ir.Path))
+	ir.Query = normalizePercentEncoding(ir.Query)
+	ir.Fragment = normalizePercentEncoding(ir.Fragment)
+	if defaultPorts[ir.Scheme] == ir.Port {
+		ir.Port = ""
+	}
+
+	return ir.String(), nil
+}
+
+var pctEncoded = regexp.MustCompile(`%[0-9A-Fa-f]{2}`)
+
+// normalizePercentEncoding decodes percent-encoded octets that represent
+// an RFC 3986 unreserved character, and uppercases the hex digits of any
+// percent-encoding left standing, per RFC 3986 §6.2.2.1/§6.2.2.2. It must
+// only be called on a component that is still in raw percent-encoded
+// form (see the comment in Normalize).
+func normalizePercentEncoding(s string) string {
+	return pctEncoded.ReplaceAllStringFunc(s, func(m string) string {
+		n, _ := strconv.ParseUint(m[1:], 16, 8)
+		b := byte(n)
+		if isUnreserved(b) {
+			return string(b)
+		}
+		return strings.ToUpper(m)
+	})
+}
+
+func isUnreserved(b byte) bool {
+	switch {
+	case 'a' <= b && b <= 'z', 'A' <= b && b <= 'Z', '0' <= b && b <= '9':
+		return true
+	case b == '-' || b == '.' || b == '_' || b == '~':
+		return true
+	}
+	return false
+}
+
+// removeDotSegments implements the remove_dot_segments algorithm of
+// RFC 3986 §5.2.4, used to resolve "." and ".." segments out of a path.
+func removeDotSegments(path string) string {
+	var out []string
+	trailingSlash := false
+	for _, seg := range strings.Split(path, "/") {
+		switch seg {
+		case ".":
+			trailingSlash = true
+		case "..":
+			// The leading "" of an absolute path is its root "/", not a
+			// segment, so ".." never removes it.
+			if len(out) > 1 || (len(out) == 1 && out[0] != "") {
+				out = out[:len(out)-1]
+			}
+			trailingSlash = true
+		default:
+			out = append(out, seg)
+			trailingSlash = false
+		}
+	}
+	result := strings.Join(out, "/")
+	if trailingSlash && !strings.HasSuffix(result, "/") {
+		result += "/"
+	}
+	return result
+}
@@ -0,0 +1,182 @@
+// Copyright 2014 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonld
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// termDef is the resolved meaning of a single JSON-LD context term: the
+// IRI it expands to, and how its values should be coerced.
+type termDef struct {
+	iri      string
+	typeIRI  string  // "@id" to coerce string values to IRIs, an xsd: IRI, or "".
+	language *string // non-nil overrides the active context's default language; "" means no language.
+	reverse  bool
+}
+
+// context is the active JSON-LD context: everything needed to expand a
+// term, compact IRI, or relative IRI reference found in a node object.
+// It is immutable once built; processing a nested node with its own
+// "@context" produces a new context rather than mutating this one, as the
+// JSON-LD context processing algorithm requires.
+type context struct {
+	terms    map[string]termDef
+	base     string
+	vocab    string
+	language string
+}
+
+func newContext(base string) context {
+	return context{terms: map[string]termDef{}, base: base}
+}
+
+// parseContext returns the context produced by applying raw (the value of
+// a "@context" member) on top of parent.
+//
+// Remote contexts (raw given as a string IRI) are not dereferenced: this
+// package only resolves contexts supplied inline, which covers the
+// embedded-context documents Cayley's loaders are expected to see.
+func parseContext(raw interface{}, parent context) (context, error) {
+	ctx := context{
+		terms:    make(map[string]termDef, len(parent.terms)),
+		base:     parent.base,
+		vocab:    parent.vocab,
+		language: parent.language,
+	}
+	for k, v := range parent.terms {
+		ctx.terms[k] = v
+	}
+
+	switch v := raw.(type) {
+	case nil:
+		return ctx, nil
+	case string:
+		// Remote context reference; left unresolved, see doc comment.
+		return ctx, nil
+	case []interface{}:
+		for _, item := range v {
+			var err error
+			ctx, err = parseContext(item, ctx)
+			if err != nil {
+				return context{}, err
+			}
+		}
+		return ctx, nil
+	case map[string]interface{}:
+		if b, ok := v["@base"].(string); ok {
+			ctx.base = resolveIRI(ctx.base, b)
+		}
+		if voc, ok := v["@vocab"].(string); ok {
+			ctx.vocab = voc
+		}
+		if lang, ok := v["@language"].(string); ok {
+			ctx.language = lang
+		}
+		for key, val := range v {
+			if isKeyword(key) {
+				continue
+			}
+			switch vv := val.(type) {
+			case string:
+				ctx.terms[key] = termDef{iri: expandIRI(ctx, vv)}
+			case map[string]interface{}:
+				td := termDef{}
+				if rev, ok := vv["@reverse"].(string); ok {
+					td.iri = expandIRI(ctx, rev)
+					td.reverse = true
+				} else if id, ok := vv["@id"].(string); ok {
+					td.iri = expandIRI(ctx, id)
+				} else {
+					td.iri = expandIRI(ctx, key)
+				}
+				if t, ok := vv["@type"].(string); ok {
+					if t == "@id" {
+						td.typeIRI = "@id"
+					} else {
+						td.typeIRI = expandIRI(ctx, t)
+					}
+				}
+				if lang, ok := vv["@language"]; ok {
+					if s, ok := lang.(string); ok {
+						td.language = &s
+					} else {
+						empty := ""
+						td.language = &empty
+					}
+				}
+				ctx.terms[key] = td
+			case nil:
+				delete(ctx.terms, key)
+			}
+		}
+		return ctx, nil
+	default:
+		return context{}, fmt.Errorf("jsonld: invalid @context value %#v", raw)
+	}
+}
+
+// expandIRI resolves a term, compact IRI, keyword, or relative/absolute
+// IRI reference to its absolute form.
+func expandIRI(ctx context, s string) string {
+	switch {
+	case s == "" || isKeyword(s) || strings.HasPrefix(s, "_:"):
+		return s
+	}
+	if td, ok := ctx.terms[s]; ok {
+		return td.iri
+	}
+	if i := strings.IndexByte(s, ':'); i > 0 {
+		prefix, suffix := s[:i], s[i+1:]
+		if !strings.HasPrefix(suffix, "//") {
+			if td, ok := ctx.terms[prefix]; ok {
+				return td.iri + suffix
+			}
+		}
+		if looksAbsolute(s) {
+			return s
+		}
+	}
+	if ctx.vocab != "" && !strings.ContainsAny(s, "/?#") {
+		return ctx.vocab + s
+	}
+	return resolveIRI(ctx.base, s)
+}
+
+func looksAbsolute(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && u.IsAbs()
+}
+
+func resolveIRI(base, ref string) string {
+	if base == "" {
+		return ref
+	}
+	b, err := url.Parse(base)
+	if err != nil {
+		return ref
+	}
+	r, err := url.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return b.ResolveReference(r).String()
+}
+
+func isKeyword(s string) bool {
+	return strings.HasPrefix(s, "@")
+}
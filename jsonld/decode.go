@@ -0,0 +1,68 @@
+// Copyright 2014 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonld
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/google/cayley/graph"
+	"github.com/google/cayley/nquads"
+)
+
+// Decoder reads a single JSON-LD document from an io.Reader and exposes
+// the quads it describes one at a time, mirroring nquads.Decoder so it
+// drops into the same loaders. Unlike nquads.Decoder, the whole document
+// must be read and expanded up front: JSON-LD's context and @graph
+// nesting can't be resolved line by line.
+type Decoder struct {
+	quads []*nquads.Quad
+	pos   int
+}
+
+// NewDecoder reads and expands the JSON-LD document in r, resolving
+// relative IRIs against opts.Base.
+func NewDecoder(r io.Reader, opts Options) (*Decoder, error) {
+	var doc interface{}
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("jsonld: %v", err)
+	}
+	quads, err := ToRDF(doc, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Decoder{quads: quads}, nil
+}
+
+// Decode returns the next quad in the document, converted to a
+// graph.Triple, or io.EOF once they are exhausted.
+func (d *Decoder) Decode() (*graph.Triple, error) {
+	if d.pos >= len(d.quads) {
+		return nil, io.EOF
+	}
+	q := d.quads[d.pos]
+	d.pos++
+	return q.Triple(), nil
+}
+
+// DecodeAll returns every remaining triple in the document.
+func (d *Decoder) DecodeAll() ([]*graph.Triple, error) {
+	ts := make([]*graph.Triple, 0, len(d.quads)-d.pos)
+	for ; d.pos < len(d.quads); d.pos++ {
+		ts = append(ts, d.quads[d.pos].Triple())
+	}
+	return ts, nil
+}
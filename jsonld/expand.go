@@ -0,0 +1,415 @@
+// Copyright 2014 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package jsonld implements the JSON-LD 1.1 "Deserialize to RDF" algorithm
+// (https://www.w3.org/TR/json-ld11-api/#deserialize-json-ld-to-rdf-algorithm),
+// converting a JSON-LD document into the same graph.Triple stream that the
+// nquads package's Decoder produces, so Cayley's loaders and its HTTP write
+// endpoint can accept JSON-LD without change.
+package jsonld
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/google/cayley/nquads"
+)
+
+const (
+	rdfType  = "http://www.w3.org/1999/02/22-rdf-syntax-ns#type"
+	rdfFirst = "http://www.w3.org/1999/02/22-rdf-syntax-ns#first"
+	rdfRest  = "http://www.w3.org/1999/02/22-rdf-syntax-ns#rest"
+	rdfNil   = "http://www.w3.org/1999/02/22-rdf-syntax-ns#nil"
+
+	xsdInteger = "http://www.w3.org/2001/XMLSchema#integer"
+	xsdDouble  = "http://www.w3.org/2001/XMLSchema#double"
+	xsdBoolean = "http://www.w3.org/2001/XMLSchema#boolean"
+)
+
+// Options controls how ToRDF resolves relative IRIs.
+type Options struct {
+	// Base is the IRI against which relative IRIs in the document (and
+	// any @base-less top-level @context) are resolved.
+	Base string
+}
+
+// ToRDF converts a decoded JSON-LD document (as produced by
+// encoding/json, i.e. map[string]interface{}/[]interface{}/string/
+// float64/bool/nil) into the quads it describes.
+func ToRDF(doc interface{}, opts Options) ([]*nquads.Quad, error) {
+	c := &converter{}
+	root := newContext(opts.Base)
+
+	if m, ok := doc.(map[string]interface{}); ok {
+		if cv, ok := m["@context"]; ok {
+			nc, err := parseContext(cv, root)
+			if err != nil {
+				return nil, err
+			}
+			root = nc
+		}
+		// A root object whose only meaningful key is @graph is the
+		// JSON-LD "top-level graph container" idiom: it lists
+		// default-graph nodes without describing a node of its own, so
+		// its @graph contents are processed directly rather than as a
+		// nested named graph. A root object that also has an @id or
+		// other properties (e.g. {"@id":"urn:g1","@graph":[...],
+		// "urn:comment":"x"}) IS itself a node, so it goes through
+		// processNode like any other node object: that already handles
+		// @graph as a nested named graph scoped by the node's own
+		// subject, and it won't silently drop the node's other
+		// properties the way unwrapping @graph here would.
+		if isGraphContainer(m) {
+			var quads []*nquads.Quad
+			for _, item := range toSlice(m["@graph"]) {
+				_, qs, err := c.processNode(root, item, nil)
+				if err != nil {
+					return nil, err
+				}
+				quads = append(quads, qs...)
+			}
+			return quads, nil
+		}
+		_, quads, err := c.processNode(root, doc, nil)
+		return quads, err
+	}
+
+	var quads []*nquads.Quad
+	for _, item := range toSlice(doc) {
+		_, qs, err := c.processNode(root, item, nil)
+		if err != nil {
+			return nil, err
+		}
+		quads = append(quads, qs...)
+	}
+	return quads, nil
+}
+
+// isGraphContainer reports whether m is the JSON-LD top-level graph
+// container idiom: an object with no identity of its own, whose only
+// keys (besides @context) are @graph.
+func isGraphContainer(m map[string]interface{}) bool {
+	if _, ok := m["@graph"]; !ok {
+		return false
+	}
+	for key := range m {
+		if key != "@graph" && key != "@context" {
+			return false
+		}
+	}
+	return true
+}
+
+// converter holds the state that must be shared across an entire
+// document's conversion: the counter used to mint fresh blank node labels
+// for anonymous nodes and RDF list cells.
+type converter struct {
+	blankCount int
+}
+
+func (c *converter) freshBlank() nquads.Term {
+	n := c.blankCount
+	c.blankCount++
+	return nquads.BlankNode(fmt.Sprintf("jsonld%d", n))
+}
+
+func termForRef(s string) nquads.Term {
+	if len(s) >= 2 && s[:2] == "_:" {
+		return nquads.BlankNode(s[2:])
+	}
+	return nquads.IRI(s)
+}
+
+// processNode converts a single JSON-LD node object into its subject Term
+// and the quads describing it, recursing into any embedded node objects,
+// reverse properties, and nested named graphs it contains. graphTerm is
+// the Provenance the resulting quads are scoped to (nil for the default
+// graph).
+func (c *converter) processNode(ctx context, raw interface{}, graphTerm nquads.Term) (nquads.Term, []*nquads.Quad, error) {
+	node, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, nil, fmt.Errorf("jsonld: expected a node object, got %T", raw)
+	}
+
+	if cv, ok := node["@context"]; ok {
+		nc, err := parseContext(cv, ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		ctx = nc
+	}
+
+	subject := c.subjectFor(ctx, node)
+	var quads []*nquads.Quad
+
+	if tv, ok := node["@type"]; ok {
+		for _, item := range toSlice(tv) {
+			s, ok := item.(string)
+			if !ok {
+				return nil, nil, fmt.Errorf("jsonld: @type value must be a string, got %#v", item)
+			}
+			quads = append(quads, &nquads.Quad{
+				Subject: subject, Predicate: nquads.IRI(rdfType), Object: termForRef(expandIRI(ctx, s)), Graph: graphTerm,
+			})
+		}
+	}
+
+	if gv, ok := node["@graph"]; ok {
+		for _, item := range toSlice(gv) {
+			_, qs, err := c.processNode(ctx, item, subject)
+			if err != nil {
+				return nil, nil, err
+			}
+			quads = append(quads, qs...)
+		}
+	}
+
+	if rv, ok := node["@reverse"]; ok {
+		rmap, ok := rv.(map[string]interface{})
+		if !ok {
+			return nil, nil, fmt.Errorf("jsonld: @reverse value must be an object, got %#v", rv)
+		}
+		for key, val := range rmap {
+			qs, err := c.expandReverse(ctx, expandIRI(ctx, key), val, subject, graphTerm)
+			if err != nil {
+				return nil, nil, err
+			}
+			quads = append(quads, qs...)
+		}
+	}
+
+	for key, val := range node {
+		if isKeyword(key) {
+			continue
+		}
+		td := ctx.terms[key]
+		pred := td.iri
+		if pred == "" {
+			pred = expandIRI(ctx, key)
+		}
+		if td.reverse {
+			qs, err := c.expandReverse(ctx, pred, val, subject, graphTerm)
+			if err != nil {
+				return nil, nil, err
+			}
+			quads = append(quads, qs...)
+			continue
+		}
+
+		objs, qs, err := c.expandValue(ctx, td, val, graphTerm)
+		if err != nil {
+			return nil, nil, err
+		}
+		quads = append(quads, qs...)
+		for _, o := range objs {
+			quads = append(quads, &nquads.Quad{Subject: subject, Predicate: nquads.IRI(pred), Object: o, Graph: graphTerm})
+		}
+	}
+
+	return subject, quads, nil
+}
+
+func (c *converter) expandReverse(ctx context, pred string, val interface{}, subject, graphTerm nquads.Term) ([]*nquads.Quad, error) {
+	var quads []*nquads.Quad
+	for _, item := range toSlice(val) {
+		objSubj, qs, err := c.processNode(ctx, item, graphTerm)
+		if err != nil {
+			return nil, err
+		}
+		quads = append(quads, qs...)
+		quads = append(quads, &nquads.Quad{Subject: objSubj, Predicate: nquads.IRI(pred), Object: subject, Graph: graphTerm})
+	}
+	return quads, nil
+}
+
+func (c *converter) subjectFor(ctx context, node map[string]interface{}) nquads.Term {
+	if id, ok := node["@id"].(string); ok && id != "" {
+		return termForRef(expandIRI(ctx, id))
+	}
+	return c.freshBlank()
+}
+
+// expandValue converts a property value (already looked up via its
+// term's coercion rules in td) into the object Terms it denotes, plus any
+// quads needed to describe them (embedded nodes, RDF list cells).
+func (c *converter) expandValue(ctx context, td termDef, raw interface{}, graphTerm nquads.Term) ([]nquads.Term, []*nquads.Quad, error) {
+	if m, ok := raw.(map[string]interface{}); ok {
+		if lv, ok := m["@list"]; ok {
+			head, qs, err := c.buildList(ctx, td, toSlice(lv), graphTerm)
+			if err != nil {
+				return nil, nil, err
+			}
+			return []nquads.Term{head}, qs, nil
+		}
+		if sv, ok := m["@set"]; ok {
+			return c.expandValue(ctx, td, sv, graphTerm)
+		}
+	}
+
+	var objs []nquads.Term
+	var quads []*nquads.Quad
+	for _, item := range toSlice(raw) {
+		o, qs, err := c.expandSingular(ctx, td, item, graphTerm)
+		if err != nil {
+			return nil, nil, err
+		}
+		quads = append(quads, qs...)
+		objs = append(objs, o)
+	}
+	return objs, quads, nil
+}
+
+func (c *converter) expandSingular(ctx context, td termDef, item interface{}, graphTerm nquads.Term) (nquads.Term, []*nquads.Quad, error) {
+	switch v := item.(type) {
+	case map[string]interface{}:
+		if val, ok := v["@value"]; ok {
+			lit, err := literalFromValue(ctx, v, val)
+			return lit, nil, err
+		}
+		if id, ok := v["@id"]; ok && len(v) == 1 {
+			s, ok := id.(string)
+			if !ok {
+				return nil, nil, fmt.Errorf("jsonld: @id value must be a string, got %#v", id)
+			}
+			return termForRef(expandIRI(ctx, s)), nil, nil
+		}
+		return c.processNode(ctx, v, graphTerm)
+	case string:
+		if td.typeIRI == "@id" {
+			return termForRef(expandIRI(ctx, v)), nil, nil
+		}
+		return literalFromScalar(ctx, td, v), nil, nil
+	case float64, bool:
+		return literalFromScalar(ctx, td, v), nil, nil
+	default:
+		return nil, nil, fmt.Errorf("jsonld: unsupported value %#v", item)
+	}
+}
+
+func literalFromValue(ctx context, obj map[string]interface{}, val interface{}) (nquads.Literal, error) {
+	lit := nquads.Literal{}
+	isString := false
+	switch vv := val.(type) {
+	case string:
+		lit.Value = vv
+		isString = true
+	case float64:
+		lit.Value = formatNumber(vv)
+	case bool:
+		lit.Value = strconv.FormatBool(vv)
+	default:
+		return nquads.Literal{}, fmt.Errorf("jsonld: unsupported @value %#v", val)
+	}
+	switch {
+	case obj["@type"] != nil:
+		t, ok := obj["@type"].(string)
+		if !ok {
+			return nquads.Literal{}, fmt.Errorf("jsonld: @type of a value object must be a string, got %#v", obj["@type"])
+		}
+		lit.Datatype = nquads.IRI(expandIRI(ctx, t))
+	case obj["@language"] != nil:
+		l, ok := obj["@language"].(string)
+		if !ok {
+			return nquads.Literal{}, fmt.Errorf("jsonld: @language must be a string, got %#v", obj["@language"])
+		}
+		lit.Lang = l
+	case isString && ctx.language != "":
+		lit.Lang = ctx.language
+	case !isString:
+		lit.Datatype = nquads.IRI(datatypeForScalar(val))
+	}
+	return lit, nil
+}
+
+func literalFromScalar(ctx context, td termDef, v interface{}) nquads.Term {
+	lit := nquads.Literal{}
+	switch vv := v.(type) {
+	case string:
+		lit.Value = vv
+		switch {
+		case td.typeIRI != "" && td.typeIRI != "@id":
+			lit.Datatype = nquads.IRI(td.typeIRI)
+		case td.language != nil:
+			lit.Lang = *td.language
+		case ctx.language != "":
+			lit.Lang = ctx.language
+		}
+	case float64:
+		lit.Value = formatNumber(vv)
+		lit.Datatype = nquads.IRI(datatypeForScalar(vv))
+	case bool:
+		lit.Value = strconv.FormatBool(vv)
+		lit.Datatype = nquads.IRI(xsdBoolean)
+	}
+	return lit
+}
+
+func datatypeForScalar(v interface{}) string {
+	switch vv := v.(type) {
+	case bool:
+		return xsdBoolean
+	case float64:
+		if vv == math.Trunc(vv) && !math.IsInf(vv, 0) {
+			return xsdInteger
+		}
+		return xsdDouble
+	}
+	return xsdDouble
+}
+
+func formatNumber(f float64) string {
+	if f == math.Trunc(f) && !math.IsInf(f, 0) {
+		return strconv.FormatInt(int64(f), 10)
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// buildList expands items into an RDF list (a chain of rdf:first/rdf:rest
+// cells terminated by rdf:nil) and returns the Term identifying its head.
+func (c *converter) buildList(ctx context, td termDef, items []interface{}, graphTerm nquads.Term) (nquads.Term, []*nquads.Quad, error) {
+	if len(items) == 0 {
+		return nquads.IRI(rdfNil), nil, nil
+	}
+
+	var head, prev nquads.Term
+	var quads []*nquads.Quad
+	for i, item := range items {
+		cell := c.freshBlank()
+		if i == 0 {
+			head = cell
+		} else {
+			quads = append(quads, &nquads.Quad{Subject: prev, Predicate: nquads.IRI(rdfRest), Object: cell, Graph: graphTerm})
+		}
+		o, qs, err := c.expandSingular(ctx, td, item, graphTerm)
+		if err != nil {
+			return nil, nil, err
+		}
+		quads = append(quads, qs...)
+		quads = append(quads, &nquads.Quad{Subject: cell, Predicate: nquads.IRI(rdfFirst), Object: o, Graph: graphTerm})
+		prev = cell
+	}
+	quads = append(quads, &nquads.Quad{Subject: prev, Predicate: nquads.IRI(rdfRest), Object: nquads.IRI(rdfNil), Graph: graphTerm})
+	return head, quads, nil
+}
+
+func toSlice(v interface{}) []interface{} {
+	if v == nil {
+		return nil
+	}
+	if arr, ok := v.([]interface{}); ok {
+		return arr
+	}
+	return []interface{}{v}
+}
@@ -0,0 +1,142 @@
+// Copyright 2014 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonld
+
+import (
+	"encoding/json"
+	"sort"
+	"testing"
+
+	"github.com/google/cayley/nquads"
+)
+
+func quadStrings(t *testing.T, quads []*nquads.Quad) []string {
+	t.Helper()
+	var out []string
+	for _, q := range quads {
+		out = append(out, q.Triple().String())
+	}
+	sort.Strings(out)
+	return out
+}
+
+func toRDF(t *testing.T, doc string) []string {
+	t.Helper()
+	var v interface{}
+	if err := json.Unmarshal([]byte(doc), &v); err != nil {
+		t.Fatalf("invalid test fixture: %v", err)
+	}
+	quads, err := ToRDF(v, Options{})
+	if err != nil {
+		t.Fatalf("ToRDF: %v", err)
+	}
+	return quadStrings(t, quads)
+}
+
+func TestToRDFBasicNode(t *testing.T) {
+	doc := `{
+		"@context": {"name": "http://schema.org/name"},
+		"@id": "http://example.org/bob",
+		"name": "Bob"
+	}`
+	got := toRDF(t, doc)
+	want := []string{`<http://example.org/bob> -- <http://schema.org/name> -> "Bob"`}
+	if len(got) != 1 || got[0] != want[0] {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
+func TestToRDFTypeCoercionAndType(t *testing.T) {
+	doc := `{
+		"@context": {
+			"knows": {"@id": "http://xmlns.com/foaf/0.1/knows", "@type": "@id"}
+		},
+		"@id": "http://example.org/bob",
+		"@type": "http://xmlns.com/foaf/0.1/Person",
+		"knows": "http://example.org/alice"
+	}`
+	got := toRDF(t, doc)
+	want := []string{
+		`<http://example.org/bob> -- <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> -> <http://xmlns.com/foaf/0.1/Person>`,
+		`<http://example.org/bob> -- <http://xmlns.com/foaf/0.1/knows> -> <http://example.org/alice>`,
+	}
+	sort.Strings(want)
+	if len(got) != len(want) {
+		t.Fatalf("got %v want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("got %v want %v", got, want)
+			break
+		}
+	}
+}
+
+func TestToRDFNamedGraph(t *testing.T) {
+	doc := `{
+		"@context": {"name": "http://schema.org/name"},
+		"@graph": [
+			{
+				"@id": "http://example.org/g1",
+				"@graph": [
+					{"@id": "http://example.org/bob", "name": "Bob"}
+				]
+			}
+		]
+	}`
+	got := toRDF(t, doc)
+	want := []string{`<http://example.org/bob> -- <http://schema.org/name> -> "Bob" (<http://example.org/g1>)`}
+	if len(got) != 1 || got[0] != want[0] {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
+func TestToRDFRootNodeWithGraphAndOtherProperties(t *testing.T) {
+	doc := `{
+		"@context": {"comment": "http://example.org/comment", "name": "http://schema.org/name"},
+		"@id": "http://example.org/g1",
+		"@graph": [
+			{"@id": "http://example.org/bob", "name": "Bob"}
+		],
+		"comment": "x"
+	}`
+	got := toRDF(t, doc)
+	want := []string{
+		`<http://example.org/bob> -- <http://schema.org/name> -> "Bob" (<http://example.org/g1>)`,
+		`<http://example.org/g1> -- <http://example.org/comment> -> "x"`,
+	}
+	sort.Strings(want)
+	if len(got) != len(want) {
+		t.Fatalf("got %v want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("got %v want %v", got, want)
+			break
+		}
+	}
+}
+
+func TestToRDFList(t *testing.T) {
+	doc := `{
+		"@context": {"items": {"@id": "http://example.org/items", "@container": "@list"}},
+		"@id": "http://example.org/bob",
+		"items": {"@list": ["a", "b"]}
+	}`
+	got := toRDF(t, doc)
+	if len(got) != 5 {
+		t.Fatalf("expected 5 quads for a 2-element RDF list, got %d: %v", len(got), got)
+	}
+}
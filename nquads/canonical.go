@@ -0,0 +1,222 @@
+// Copyright 2014 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nquads
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/cayley/graph"
+)
+
+// Canonicalize writes quads to w as a deterministic N-Quads serialization:
+// literals are escaped with the minimal N-Triples escape set, quads are
+// ordered by (graph, subject, predicate, object) using byte comparison of
+// their lexical forms, and blank nodes are relabelled to "_:c14nN" by an
+// iterative hash-based canonicalization in the style of the RDF Dataset
+// Canonicalization algorithm: each blank node's hash starts from its
+// incident quads (with itself marked and every other blank node blinded),
+// then is repeatedly re-hashed against its neighbors' previous-round
+// hashes until no hash changes, for at most one round more than there are
+// blank nodes. The result depends only on the dataset's
+// structure, not on the input's blank node labels or ordering.
+//
+// Unlike the full RDF Dataset Canonicalization specification, ties left
+// after hashing converges are broken by original blank node label rather
+// than by an exhaustive permutation search; this is sufficient for
+// datasets whose blank nodes aren't fully symmetric under the dataset's
+// structure.
+func Canonicalize(w io.Writer, quads []*graph.Triple) error {
+	cq, err := toQuads(quads)
+	if err != nil {
+		return err
+	}
+	relabel(cq)
+
+	out := make([]*graph.Triple, len(cq))
+	for i, q := range cq {
+		out[i] = q.Triple()
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.Provenance != b.Provenance {
+			return a.Provenance < b.Provenance
+		}
+		if a.Subject != b.Subject {
+			return a.Subject < b.Subject
+		}
+		if a.Predicate != b.Predicate {
+			return a.Predicate < b.Predicate
+		}
+		return a.Object < b.Object
+	})
+
+	return NewEncoder(w).EncodeAll(out)
+}
+
+// Hash returns the SHA-256 digest of quads' canonical serialization, so
+// two dumps that are semantically equal (up to blank node naming and
+// ordering) hash to the same value.
+func Hash(quads []*graph.Triple) ([32]byte, error) {
+	var buf strings.Builder
+	if err := Canonicalize(&buf, quads); err != nil {
+		return [32]byte{}, err
+	}
+	return sha256.Sum256([]byte(buf.String())), nil
+}
+
+func toQuads(triples []*graph.Triple) ([]*Quad, error) {
+	qs := make([]*Quad, len(triples))
+	for i, t := range triples {
+		sub, err := parseTerm(t.Subject)
+		if err != nil {
+			return nil, err
+		}
+		pred, err := parseTerm(t.Predicate)
+		if err != nil {
+			return nil, err
+		}
+		obj, err := parseTerm(t.Object)
+		if err != nil {
+			return nil, err
+		}
+		var g Term
+		if t.Provenance != "" {
+			g, err = parseTerm(t.Provenance)
+			if err != nil {
+				return nil, err
+			}
+		}
+		qs[i] = &Quad{Subject: sub, Predicate: pred, Object: obj, Graph: g}
+	}
+	return qs, nil
+}
+
+// relabel rewrites every BlankNode term across quads in place to its
+// canonical "_:c14nN" label.
+func relabel(quads []*Quad) {
+	adjacency := map[string][]*Quad{}
+	for _, q := range quads {
+		for _, t := range []Term{q.Subject, q.Predicate, q.Object, q.Graph} {
+			if bn, ok := t.(BlankNode); ok {
+				l := string(bn)
+				adjacency[l] = append(adjacency[l], q)
+			}
+		}
+	}
+	if len(adjacency) == 0 {
+		return
+	}
+
+	labels := make([]string, 0, len(adjacency))
+	for l := range adjacency {
+		labels = append(labels, l)
+	}
+	sort.Strings(labels)
+
+	hashes := map[string][32]byte{}
+	for _, l := range labels {
+		hashes[l] = hashQuads(adjacency[l], l, nil)
+	}
+	for round := 0; round < len(labels)+1; round++ {
+		next := map[string][32]byte{}
+		changed := false
+		for _, l := range labels {
+			h := hashQuads(adjacency[l], l, hashes)
+			next[l] = h
+			if h != hashes[l] {
+				changed = true
+			}
+		}
+		hashes = next
+		if !changed {
+			break
+		}
+	}
+
+	sort.Slice(labels, func(i, j int) bool {
+		hi, hj := hashes[labels[i]], hashes[labels[j]]
+		if hi != hj {
+			return hex.EncodeToString(hi[:]) < hex.EncodeToString(hj[:])
+		}
+		return labels[i] < labels[j]
+	})
+
+	canonical := make(map[string]BlankNode, len(labels))
+	for i, l := range labels {
+		canonical[l] = BlankNode("c14n" + strconv.Itoa(i))
+	}
+
+	for _, q := range quads {
+		q.Subject = relabelTerm(q.Subject, canonical)
+		q.Predicate = relabelTerm(q.Predicate, canonical)
+		q.Object = relabelTerm(q.Object, canonical)
+		q.Graph = relabelTerm(q.Graph, canonical)
+	}
+}
+
+func relabelTerm(t Term, canonical map[string]BlankNode) Term {
+	bn, ok := t.(BlankNode)
+	if !ok {
+		return t
+	}
+	return canonical[string(bn)]
+}
+
+// hashQuads hashes the quads incident to blank node label, with label
+// itself rendered as the fixed placeholder "_:a" and every other blank
+// node rendered either as the generic placeholder "_:z" (prevHashes nil,
+// the first round) or as its previous-round hash (subsequent rounds), so
+// later rounds let each blank node's hash absorb more of its neighbors'
+// identities.
+func hashQuads(qs []*Quad, label string, prevHashes map[string][32]byte) [32]byte {
+	override := func(l string) string {
+		if l == label {
+			return "_:a"
+		}
+		if prevHashes == nil {
+			return "_:z"
+		}
+		if h, ok := prevHashes[l]; ok {
+			return "_:h" + hex.EncodeToString(h[:])
+		}
+		return "_:z"
+	}
+
+	lines := make([]string, len(qs))
+	for i, q := range qs {
+		lines[i] = renderForHash(q, override)
+	}
+	sort.Strings(lines)
+	return sha256.Sum256([]byte(strings.Join(lines, "\n")))
+}
+
+func renderForHash(q *Quad, override func(label string) string) string {
+	render := func(t Term) string {
+		if t == nil {
+			return ""
+		}
+		if bn, ok := t.(BlankNode); ok {
+			return override(string(bn))
+		}
+		return t.String()
+	}
+	return render(q.Subject) + " " + render(q.Predicate) + " " + render(q.Object) + " " + render(q.Graph)
+}
+
@@ -0,0 +1,77 @@
+// Copyright 2014 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nquads
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/cayley/graph"
+)
+
+func mustParse(t *testing.T, line string) *graph.Triple {
+	t.Helper()
+	tr, err := Parse(line)
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", line, err)
+	}
+	return tr
+}
+
+func TestCanonicalizeDistinguishesDifferentlyShapedGraphs(t *testing.T) {
+	a := []*graph.Triple{
+		mustParse(t, `_:x <http://example.org/knows> _:y .`),
+		mustParse(t, `_:y <http://example.org/name> "Bob" .`),
+	}
+	b := []*graph.Triple{
+		mustParse(t, `_:bob <http://example.org/knows> _:alice .`),
+		mustParse(t, `_:bob <http://example.org/name> "Bob" .`),
+	}
+
+	var bufA, bufB bytes.Buffer
+	if err := Canonicalize(&bufA, a); err != nil {
+		t.Fatalf("Canonicalize(a): %v", err)
+	}
+	if err := Canonicalize(&bufB, b); err != nil {
+		t.Fatalf("Canonicalize(b): %v", err)
+	}
+
+	if bufA.String() == bufB.String() {
+		t.Fatalf("expected differently-shaped blank node graphs to canonicalize differently, both produced:\n%s", bufA.String())
+	}
+}
+
+func TestCanonicalizeIsDeterministicAcrossBlankNodeNaming(t *testing.T) {
+	a := []*graph.Triple{
+		mustParse(t, `_:x <http://example.org/knows> _:y .`),
+		mustParse(t, `<http://example.org/root> <http://example.org/has> _:x .`),
+	}
+	b := []*graph.Triple{
+		mustParse(t, `_:n1 <http://example.org/knows> _:n2 .`),
+		mustParse(t, `<http://example.org/root> <http://example.org/has> _:n1 .`),
+	}
+
+	ha, err := Hash(a)
+	if err != nil {
+		t.Fatalf("Hash(a): %v", err)
+	}
+	hb, err := Hash(b)
+	if err != nil {
+		t.Fatalf("Hash(b): %v", err)
+	}
+	if ha != hb {
+		t.Errorf("expected isomorphic graphs under blank node renaming to hash equal, got %x != %x", ha, hb)
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright 2014 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nquads
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/google/cayley/graph"
+)
+
+// maxLineSize bounds how long a single N-Triples/N-Quads line (i.e. one
+// triple, including its literal) may be. bufio.Scanner's own default of
+// 64KB is too small for dumps whose literals hold whole documents, so
+// Decoder raises it here; a line past even this bound is reported as a
+// *SyntaxError rather than as a bare bufio error, same as any other
+// malformed line.
+const maxLineSize = 64 * 1024 * 1024
+
+// SyntaxError records a parse failure at a specific position in an
+// N-Triples/N-Quads stream, so callers loading multi-gigabyte dumps can
+// report exactly where a dump went bad instead of just "parse failed".
+type SyntaxError struct {
+	Line    int    // 1-based line number of the offending line.
+	Col     int    // 1-based column of the first non-blank rune on the line.
+	Snippet string // The offending line, for inclusion in log output.
+	Err     error  // The underlying parse error.
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("nquads: syntax error at line %d, column %d: %v: %q", e.Line, e.Col, e.Err, e.Snippet)
+}
+
+func (e *SyntaxError) Unwrap() error { return e.Err }
+
+// Decoder reads a stream of N-Triples or N-Quads text, one triple at a
+// time, without buffering the whole input in memory. Blank lines and
+// lines whose first non-blank rune is "#" are skipped.
+type Decoder struct {
+	s    *bufio.Scanner
+	line int
+}
+
+// NewDecoder returns a Decoder that reads N-Triples/N-Quads text from r.
+func NewDecoder(r io.Reader) *Decoder {
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	return &Decoder{s: s}
+}
+
+// Decode reads and parses the next non-blank, non-comment line from the
+// stream. It returns io.EOF once the stream is exhausted.
+func (d *Decoder) Decode() (*graph.Triple, error) {
+	for {
+		if !d.s.Scan() {
+			if err := d.s.Err(); err != nil {
+				if errors.Is(err, bufio.ErrTooLong) {
+					err = fmt.Errorf("line exceeds the %d byte limit", maxLineSize)
+				}
+				return nil, &SyntaxError{
+					Line: d.line + 1,
+					Col:  1,
+					Err:  err,
+				}
+			}
+			return nil, io.EOF
+		}
+		d.line++
+		line := d.s.Text()
+
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		t, err := Parse(line)
+		if err != nil {
+			return nil, &SyntaxError{
+				Line:    d.line,
+				Col:     len(line) - len(trimmed) + 1,
+				Snippet: line,
+				Err:     err,
+			}
+		}
+		return t, nil
+	}
+}
+
+// DecodeAll reads the remainder of the stream and returns every triple it
+// contains. It returns the triples decoded so far alongside the first
+// error encountered, if any.
+func (d *Decoder) DecodeAll() ([]*graph.Triple, error) {
+	var ts []*graph.Triple
+	for {
+		t, err := d.Decode()
+		if err == io.EOF {
+			return ts, nil
+		}
+		if err != nil {
+			return ts, err
+		}
+		ts = append(ts, t)
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright 2014 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nquads
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const testDump = `# a leading comment
+
+<http://example.org/bob> <http://example.org/knows> <http://example.org/alice> .
+
+  <http://example.org/alice> <http://example.org/knows> <http://example.org/bob> . # trailing
+this is not a valid line
+`
+
+func TestDecodeAll(t *testing.T) {
+	d := NewDecoder(strings.NewReader(testDump))
+	ts, err := d.DecodeAll()
+	if len(ts) != 2 {
+		t.Fatalf("expected 2 triples decoded before the error, got %d", len(ts))
+	}
+	se, ok := err.(*SyntaxError)
+	if !ok {
+		t.Fatalf("expected a *SyntaxError, got %#v", err)
+	}
+	if se.Line != 6 {
+		t.Errorf("expected the error to be reported on line 6, got %d", se.Line)
+	}
+}
+
+func TestDecodeAllowsLinesPastBufioDefault(t *testing.T) {
+	// bufio.Scanner's own default token limit is 64KB; a literal bigger
+	// than that must still decode instead of failing with a bare
+	// "token too long".
+	big := strings.Repeat("x", 128*1024)
+	dump := `<http://example.org/bob> <http://example.org/name> "` + big + `" .` + "\n"
+	d := NewDecoder(strings.NewReader(dump))
+	tr, err := d.Decode()
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if tr.Object != `"`+big+`"` {
+		t.Errorf("long literal was not decoded intact")
+	}
+}
+
+func TestDecodeReportsOverLongLineAsSyntaxError(t *testing.T) {
+	d := NewDecoder(strings.NewReader(strings.Repeat("x", maxLineSize+1) + "\n"))
+	_, err := d.Decode()
+	if _, ok := err.(*SyntaxError); !ok {
+		t.Fatalf("expected a *SyntaxError for a line past maxLineSize, got %#v", err)
+	}
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	in := `<http://example.org/bob> <http://example.org/knows> <http://example.org/alice> <http://example.org/g> .`
+	tr, err := Parse(in)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := NewEncoder(&buf).Encode(tr); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	got, err := Parse(buf.String())
+	if err != nil {
+		t.Fatalf("Parse(Encode(t)): %v", err)
+	}
+	if *got != *tr {
+		t.Errorf("round trip mismatch: got %+v want %+v", got, tr)
+	}
+
+	buf.Reset()
+	e := NewEncoder(&buf)
+	e.Mode = NTriples
+	if err := e.Encode(tr); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	if strings.Contains(buf.String(), "http://example.org/g") {
+		t.Errorf("N-Triples mode should have dropped the graph term, got %q", buf.String())
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright 2014 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nquads
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/google/cayley/graph"
+)
+
+// Mode selects which grammar an Encoder writes: N-Quads (the default)
+// includes each triple's Provenance as a fourth term, N-Triples drops it.
+type Mode int
+
+const (
+	NQuads Mode = iota
+	NTriples
+)
+
+// Encoder writes a stream of graph.Triple values out as N-Triples or
+// N-Quads text, one line per triple, mirroring the way Decoder reads them
+// back in.
+type Encoder struct {
+	w    io.Writer
+	Mode Mode
+}
+
+// NewEncoder returns an Encoder that writes to w in N-Quads mode.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w, Mode: NQuads}
+}
+
+// Encode writes t as a single line of N-Triples or N-Quads text.
+func (e *Encoder) Encode(t *graph.Triple) error {
+	parts := []string{t.Subject, t.Predicate, t.Object}
+	if e.Mode == NQuads && t.Provenance != "" {
+		parts = append(parts, t.Provenance)
+	}
+	_, err := fmt.Fprintf(e.w, "%s .\n", strings.Join(parts, " "))
+	return err
+}
+
+// EncodeAll writes every triple in ts.
+func (e *Encoder) EncodeAll(ts []*graph.Triple) error {
+	for _, t := range ts {
+		if err := e.Encode(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,292 @@
+// Copyright 2014 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package nquads implements a reader for RDF 1.1 N-Triples and N-Quads, as
+// described in http://www.w3.org/TR/n-quads/ and http://www.w3.org/TR/n-triples/.
+package nquads
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/cayley/graph"
+)
+
+const (
+	iriRef  = `<[^>]*>`
+	blank   = `_:[A-Za-z0-9][A-Za-z0-9_:.-]*`
+	literal = `"(?:[^"\\]|\\.)*"(?:@[A-Za-z]+(?:-[A-Za-z0-9]+)*|\^\^` + iriRef + `)?`
+)
+
+var (
+	subjectPat = `(?:` + iriRef + `|` + blank + `)`
+	objectPat  = `(?:` + iriRef + `|` + blank + `|` + literal + `)`
+
+	lineRe = regexp.MustCompile(
+		`^\s*(` + subjectPat + `)\s+(` + iriRef + `)\s+(` + objectPat + `)(?:\s+(` + subjectPat + `))?\s*\.\s*(?:#.*)?$`,
+	)
+)
+
+// Parse parses a single line of N-Triples or N-Quads text and returns the
+// triple it describes. Blank lines and comment-only lines are not valid
+// input to Parse; use a Decoder to skip over them when reading a stream.
+func Parse(line string) (*graph.Triple, error) {
+	m := lineRe.FindStringSubmatch(line)
+	if m == nil {
+		return nil, fmt.Errorf("nquads: could not parse line %q", line)
+	}
+	return &graph.Triple{
+		Subject:    m[1],
+		Predicate:  m[2],
+		Object:     m[3],
+		Provenance: m[4],
+	}, nil
+}
+
+// Term is the structured form of an RDF term: an IRI, a BlankNode, or a
+// Literal. It follows the RDF term model used by the RDF.js term
+// interfaces and the JSON-LD to_rdf/from_rdf algorithms, so stores and
+// higher layers can work with typed terms instead of re-parsing the raw
+// N-Quads lexical forms that graph.Triple carries.
+type Term interface {
+	// String returns the term's N-Quads lexical form, e.g. "<iri>",
+	// "_:b0" or "\"lit\"@en".
+	String() string
+	isTerm()
+}
+
+// IRI is an absolute IRI reference term, without its enclosing "<" ">".
+type IRI string
+
+func (v IRI) String() string { return "<" + string(v) + ">" }
+func (IRI) isTerm()           {}
+
+// BlankNode is a blank node term, without its "_:" prefix.
+type BlankNode string
+
+func (v BlankNode) String() string { return "_:" + string(v) }
+func (BlankNode) isTerm()          {}
+
+// Literal is an RDF literal term: a lexical Value together with either a
+// Lang tag or a Datatype IRI (never both; an untagged, untyped literal has
+// neither set, and is implicitly xsd:string).
+type Literal struct {
+	Value    string
+	Lang     string
+	Datatype IRI
+}
+
+func (v Literal) String() string {
+	s := `"` + escape(v.Value) + `"`
+	switch {
+	case v.Lang != "":
+		s += "@" + v.Lang
+	case v.Datatype != "":
+		s += "^^" + v.Datatype.String()
+	}
+	return s
+}
+func (Literal) isTerm() {}
+
+// Quad is the structured counterpart of graph.Triple: the same
+// subject/predicate/object/graph shape, but with each position resolved to
+// a typed Term instead of a raw lexical string. Graph is nil in the
+// default graph.
+type Quad struct {
+	Subject   Term
+	Predicate Term
+	Object    Term
+	Graph     Term
+}
+
+// Triple converts q back to the raw-string graph.Triple representation
+// that the rest of Cayley already knows how to store and query.
+func (q *Quad) Triple() *graph.Triple {
+	t := &graph.Triple{
+		Subject:   q.Subject.String(),
+		Predicate: q.Predicate.String(),
+		Object:    q.Object.String(),
+	}
+	if q.Graph != nil {
+		t.Provenance = q.Graph.String()
+	}
+	return t
+}
+
+// ParseTerms parses a single line of N-Triples or N-Quads text, as Parse
+// does, but returns a Quad of typed Terms rather than a graph.Triple of raw
+// strings.
+func ParseTerms(line string) (*Quad, error) {
+	t, err := Parse(line)
+	if err != nil {
+		return nil, err
+	}
+	sub, err := parseTerm(t.Subject)
+	if err != nil {
+		return nil, err
+	}
+	pred, err := parseTerm(t.Predicate)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := parseTerm(t.Object)
+	if err != nil {
+		return nil, err
+	}
+	q := &Quad{Subject: sub, Predicate: pred, Object: obj}
+	if t.Provenance != "" {
+		g, err := parseTerm(t.Provenance)
+		if err != nil {
+			return nil, err
+		}
+		q.Graph = g
+	}
+	return q, nil
+}
+
+// parseTerm converts the lexical form of a single N-Quads term (as found in
+// one of graph.Triple's fields) into its typed Term.
+func parseTerm(s string) (Term, error) {
+	switch {
+	case strings.HasPrefix(s, "<") && strings.HasSuffix(s, ">"):
+		return IRI(s[1 : len(s)-1]), nil
+	case strings.HasPrefix(s, "_:"):
+		return BlankNode(s[2:]), nil
+	case strings.HasPrefix(s, `"`):
+		return parseLiteral(s)
+	}
+	return nil, fmt.Errorf("nquads: could not parse term %q", s)
+}
+
+func parseLiteral(s string) (Term, error) {
+	end := strings.LastIndex(s, `"`)
+	if end <= 0 {
+		return nil, fmt.Errorf("nquads: could not parse literal %q", s)
+	}
+	lit := Literal{Value: unescape(s[1:end])}
+	switch rest := s[end+1:]; {
+	case strings.HasPrefix(rest, "@"):
+		lit.Lang = rest[1:]
+	case strings.HasPrefix(rest, "^^"):
+		dt := rest[2:]
+		if !strings.HasPrefix(dt, "<") || !strings.HasSuffix(dt, ">") {
+			return nil, fmt.Errorf("nquads: could not parse literal datatype %q", rest)
+		}
+		lit.Datatype = IRI(dt[1 : len(dt)-1])
+	case rest != "":
+		return nil, fmt.Errorf("nquads: trailing text on literal %q", s)
+	}
+	return lit, nil
+}
+
+// unescape decodes a literal's lexical form: the ECHAR escapes
+// (\t \b \n \r \f \" \' \\) and the \uXXXX / \UXXXXXXXX UCHAR escapes
+// that let a literal stay ASCII-only. An escape with too few or
+// non-hex digits is left as-is rather than rejected, matching the
+// leave-it-alone behavior of an unrecognized ECHAR.
+func unescape(s string) string {
+	if !strings.ContainsRune(s, '\\') {
+		return s
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '\\' || i+1 >= len(s) {
+			sb.WriteByte(c)
+			continue
+		}
+		switch s[i+1] {
+		case 't':
+			sb.WriteByte('\t')
+			i++
+		case 'b':
+			sb.WriteByte('\b')
+			i++
+		case 'n':
+			sb.WriteByte('\n')
+			i++
+		case 'r':
+			sb.WriteByte('\r')
+			i++
+		case 'f':
+			sb.WriteByte('\f')
+			i++
+		case '"':
+			sb.WriteByte('"')
+			i++
+		case '\'':
+			sb.WriteByte('\'')
+			i++
+		case '\\':
+			sb.WriteByte('\\')
+			i++
+		case 'u':
+			if r, ok := decodeHex(s, i+2, 4); ok {
+				sb.WriteRune(r)
+				i += 1 + 4
+			} else {
+				sb.WriteByte(c)
+			}
+		case 'U':
+			if r, ok := decodeHex(s, i+2, 8); ok {
+				sb.WriteRune(r)
+				i += 1 + 8
+			} else {
+				sb.WriteByte(c)
+			}
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
+
+// decodeHex parses the n hex digits of s starting at start into a rune,
+// reporting false if they run past the end of s or aren't all hex digits.
+func decodeHex(s string, start, n int) (rune, bool) {
+	if start+n > len(s) {
+		return 0, false
+	}
+	var v rune
+	for i := 0; i < n; i++ {
+		d := s[start+i]
+		var x rune
+		switch {
+		case d >= '0' && d <= '9':
+			x = rune(d - '0')
+		case d >= 'a' && d <= 'f':
+			x = rune(d-'a') + 10
+		case d >= 'A' && d <= 'F':
+			x = rune(d-'A') + 10
+		default:
+			return 0, false
+		}
+		v = v<<4 | x
+	}
+	return v, true
+}
+
+// escaper applies the minimal N-Triples escape set: enough to keep a
+// literal's lexical form on a single line and unambiguous to re-parse.
+var escaper = strings.NewReplacer(
+	`\`, `\\`,
+	"\n", `\n`,
+	"\r", `\r`,
+	`"`, `\"`,
+)
+
+func escape(s string) string {
+	return escaper.Replace(s)
+}
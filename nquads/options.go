@@ -0,0 +1,123 @@
+// Copyright 2014 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nquads
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/cayley/graph"
+	"github.com/google/cayley/iri"
+)
+
+// ParserOptions controls the optional IRI checking ParseWithOptions layers
+// on top of Parse's plain N-Triples/N-Quads grammar.
+type ParserOptions struct {
+	// ValidateIRIs rejects a line whose IRIREF terms are not
+	// syntactically valid IRIs (RFC 3987).
+	ValidateIRIs bool
+	// NormalizeIRIs rewrites IRIREF terms to their RFC 3986 §6
+	// syntax-based normal form, so equivalent IRIs like
+	// "HTTP://Example.com/a/./b" and "http://example.com/a/b" parse to
+	// the same string and are treated as the same node.
+	NormalizeIRIs bool
+}
+
+// ParseWithOptions parses line as Parse does, then validates and/or
+// normalizes each of its IRIREF terms according to opts: the
+// subject/predicate/object/graph positions that are themselves an
+// IRIREF, and an object literal's "^^<...>" datatype IRI, if it has one.
+// Blank nodes and a literal's value/language tag are returned unchanged.
+func ParseWithOptions(line string, opts ParserOptions) (*graph.Triple, error) {
+	t, err := Parse(line)
+	if err != nil {
+		return nil, err
+	}
+	if !opts.ValidateIRIs && !opts.NormalizeIRIs {
+		return t, nil
+	}
+	for _, f := range []*string{&t.Subject, &t.Predicate, &t.Object, &t.Provenance} {
+		if err := processIRIField(f, opts); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// processIRIField rewrites *f in place: the whole field when it is an
+// IRIREF term ("<...>"), or just its datatype when it is a literal with
+// a "^^<...>" suffix. A blank node, and a literal's value/language tag,
+// are left untouched.
+func processIRIField(f *string, opts ParserOptions) error {
+	s := *f
+	if s == "" {
+		return nil
+	}
+	if strings.HasPrefix(s, "<") && strings.HasSuffix(s, ">") {
+		norm, err := processIRIText(s[1:len(s)-1], opts)
+		if err != nil {
+			return err
+		}
+		*f = "<" + norm + ">"
+		return nil
+	}
+	if strings.HasPrefix(s, `"`) {
+		return processLiteralDatatype(f, opts)
+	}
+	return nil
+}
+
+// processLiteralDatatype rewrites *f's "^^<...>" datatype IRI in place,
+// if it has one; a language-tagged or untyped (implicitly xsd:string)
+// literal is left untouched.
+func processLiteralDatatype(f *string, opts ParserOptions) error {
+	s := *f
+	end := strings.LastIndex(s, `"`)
+	if end <= 0 {
+		return nil
+	}
+	rest := s[end+1:]
+	if !strings.HasPrefix(rest, "^^") {
+		return nil
+	}
+	dt := rest[2:]
+	if !strings.HasPrefix(dt, "<") || !strings.HasSuffix(dt, ">") {
+		return nil
+	}
+	norm, err := processIRIText(dt[1:len(dt)-1], opts)
+	if err != nil {
+		return err
+	}
+	*f = s[:end+1] + "^^<" + norm + ">"
+	return nil
+}
+
+// processIRIText validates and/or normalizes inner (an IRI's lexical
+// form, without its enclosing "<" ">") according to opts.
+func processIRIText(inner string, opts ParserOptions) (string, error) {
+	if opts.ValidateIRIs {
+		if _, err := iri.Parse(inner); err != nil {
+			return "", fmt.Errorf("nquads: invalid IRI %q: %v", inner, err)
+		}
+	}
+	if opts.NormalizeIRIs {
+		norm, err := iri.Normalize(inner)
+		if err != nil {
+			return "", fmt.Errorf("nquads: could not normalize IRI %q: %v", inner, err)
+		}
+		return norm, nil
+	}
+	return inner, nil
+}
@@ -0,0 +1,64 @@
+// Copyright 2014 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nquads
+
+import "testing"
+
+func TestParseWithOptionsNormalize(t *testing.T) {
+	in := `<HTTP://Example.com/a/./b> <http://example.org/p> <http://example.org/o> .`
+	got, err := ParseWithOptions(in, ParserOptions{NormalizeIRIs: true})
+	if err != nil {
+		t.Fatalf("ParseWithOptions: %v", err)
+	}
+	if want := "<http://example.com/a/b>"; got.Subject != want {
+		t.Errorf("Subject = %q, want %q", got.Subject, want)
+	}
+}
+
+func TestParseWithOptionsValidateRejectsIllegalIRI(t *testing.T) {
+	in := `<http://example.org/a b> <http://example.org/p> <http://example.org/o> .`
+	if _, err := ParseWithOptions(in, ParserOptions{ValidateIRIs: true}); err == nil {
+		t.Fatalf("ParseWithOptions(ValidateIRIs: true) accepted a line with a space inside an IRIREF: %q", in)
+	}
+}
+
+func TestParseWithOptionsNormalizesLiteralDatatype(t *testing.T) {
+	in := `<http://example.org/bob> <http://example.org/birthDate> "1990-07-04"^^<HTTP://Example.com/Date> .`
+	got, err := ParseWithOptions(in, ParserOptions{NormalizeIRIs: true})
+	if err != nil {
+		t.Fatalf("ParseWithOptions: %v", err)
+	}
+	if want := `"1990-07-04"^^<http://example.com/Date>`; got.Object != want {
+		t.Errorf("Object = %q, want %q", got.Object, want)
+	}
+}
+
+func TestParseWithOptionsValidateRejectsIllegalLiteralDatatype(t *testing.T) {
+	in := `<http://example.org/bob> <http://example.org/birthDate> "1990-07-04"^^<http://example.com/a b> .`
+	if _, err := ParseWithOptions(in, ParserOptions{ValidateIRIs: true}); err == nil {
+		t.Fatalf("ParseWithOptions(ValidateIRIs: true) accepted a literal with a space inside its datatype IRIREF: %q", in)
+	}
+}
+
+func TestParseWithOptionsLeavesLangTaggedLiteralAlone(t *testing.T) {
+	in := `<http://example.org/bob> <http://example.org/name> "Bob"@en .`
+	got, err := ParseWithOptions(in, ParserOptions{ValidateIRIs: true, NormalizeIRIs: true})
+	if err != nil {
+		t.Fatalf("ParseWithOptions: %v", err)
+	}
+	if want := `"Bob"@en`; got.Object != want {
+		t.Errorf("Object = %q, want %q", got.Object, want)
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2014 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nquads
+
+import (
+	"reflect"
+	"testing"
+)
+
+var testTerms = []struct {
+	message string
+	input   string
+	expect  *Quad
+}{
+	{
+		message: "parse triple of IRIs",
+		input:   `<http://example.org/bob> <http://example.org/knows> <http://example.org/alice> .`,
+		expect: &Quad{
+			Subject:   IRI("http://example.org/bob"),
+			Predicate: IRI("http://example.org/knows"),
+			Object:    IRI("http://example.org/alice"),
+		},
+	},
+	{
+		message: "parse triple with blank node subject and object",
+		input:   `_:alice <http://example.org/knows> _:bob .`,
+		expect: &Quad{
+			Subject:   BlankNode("alice"),
+			Predicate: IRI("http://example.org/knows"),
+			Object:    BlankNode("bob"),
+		},
+	},
+	{
+		message: "parse triple with plain literal object",
+		input:   `<http://example.org/bob> <http://example.org/name> "Bob" .`,
+		expect: &Quad{
+			Subject:   IRI("http://example.org/bob"),
+			Predicate: IRI("http://example.org/name"),
+			Object:    Literal{Value: "Bob"},
+		},
+	},
+	{
+		message: "parse triple with language-tagged literal object",
+		input:   `<http://example.org/bob> <http://example.org/name> "Bob"@en .`,
+		expect: &Quad{
+			Subject:   IRI("http://example.org/bob"),
+			Predicate: IRI("http://example.org/name"),
+			Object:    Literal{Value: "Bob", Lang: "en"},
+		},
+	},
+	{
+		message: "parse triple with typed literal object",
+		input:   `<http://example.org/bob> <http://example.org/birthDate> "1990-07-04"^^<http://www.w3.org/2001/XMLSchema#date> .`,
+		expect: &Quad{
+			Subject:   IRI("http://example.org/bob"),
+			Predicate: IRI("http://example.org/birthDate"),
+			Object:    Literal{Value: "1990-07-04", Datatype: IRI("http://www.w3.org/2001/XMLSchema#date")},
+		},
+	},
+	{
+		message: "parse quad with named graph",
+		input:   `<http://example.org/bob> <http://example.org/knows> <http://example.org/alice> <http://example.org/graph1> .`,
+		expect: &Quad{
+			Subject:   IRI("http://example.org/bob"),
+			Predicate: IRI("http://example.org/knows"),
+			Object:    IRI("http://example.org/alice"),
+			Graph:     IRI("http://example.org/graph1"),
+		},
+	},
+	{
+		message: "parse literal with \\u and \\U UCHAR escapes",
+		input:   `<http://example.org/bob> <http://example.org/name> "caf\u00e9 \U0001F600" .`,
+		expect: &Quad{
+			Subject:   IRI("http://example.org/bob"),
+			Predicate: IRI("http://example.org/name"),
+			Object:    Literal{Value: "café 😀"},
+		},
+	},
+}
+
+func TestParseTerms(t *testing.T) {
+	for _, test := range testTerms {
+		got, err := ParseTerms(test.input)
+		if err != nil {
+			t.Errorf("unexpected error when %s: %v", test.message, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.expect) {
+			t.Errorf("failed to %s, %q, got:%#v expect:%#v", test.message, test.input, got, test.expect)
+		}
+	}
+}
+
+func TestQuadTriple(t *testing.T) {
+	q := &Quad{
+		Subject:   IRI("http://example.org/bob"),
+		Predicate: IRI("http://example.org/knows"),
+		Object:    IRI("http://example.org/alice"),
+	}
+	got := q.Triple()
+	if got.Subject != "<http://example.org/bob>" || got.Provenance != "" {
+		t.Errorf("Quad.Triple() produced unexpected graph.Triple: %#v", got)
+	}
+}
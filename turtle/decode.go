@@ -0,0 +1,69 @@
+// Copyright 2014 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package turtle
+
+import (
+	"io"
+
+	"github.com/google/cayley/graph"
+)
+
+// Decoder reads a stream of Turtle/N3 text and exposes the triples it
+// describes one at a time, mirroring nquads.Decoder. Each Turtle
+// statement can expand into several triples (a blank node property list
+// or a collection, say); Decode queues them and drains the queue before
+// parsing the next statement.
+type Decoder struct {
+	p     *parser
+	queue []*graph.Triple
+}
+
+// NewDecoder returns a Decoder that reads Turtle/N3 text from r.
+func NewDecoder(r io.Reader, opts Options) *Decoder {
+	return &Decoder{p: newParser(r, opts)}
+}
+
+// Decode returns the next triple in the stream, or io.EOF once it is
+// exhausted.
+func (d *Decoder) Decode() (*graph.Triple, error) {
+	for len(d.queue) == 0 {
+		quads, err := d.p.nextStatement()
+		if err != nil {
+			return nil, err
+		}
+		for _, q := range quads {
+			d.queue = append(d.queue, q.Triple())
+		}
+	}
+	t := d.queue[0]
+	d.queue = d.queue[1:]
+	return t, nil
+}
+
+// DecodeAll reads the remainder of the stream and returns every triple it
+// contains.
+func (d *Decoder) DecodeAll() ([]*graph.Triple, error) {
+	var ts []*graph.Triple
+	for {
+		t, err := d.Decode()
+		if err == io.EOF {
+			return ts, nil
+		}
+		if err != nil {
+			return ts, err
+		}
+		ts = append(ts, t)
+	}
+}
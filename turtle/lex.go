@@ -0,0 +1,490 @@
+// Copyright 2014 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package turtle
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIRIRef
+	tokPNameNS
+	tokPNameLN
+	tokBlankNodeLabel
+	tokA
+	tokDot
+	tokSemicolon
+	tokComma
+	tokOpenBracket
+	tokCloseBracket
+	tokOpenParen
+	tokCloseParen
+	tokString
+	tokLangTag
+	tokDoubleCaret
+	tokInteger
+	tokDecimal
+	tokDouble
+	tokBoolean
+	tokPrefixKeyword
+	tokBaseKeyword
+	tokImpliedBy // N3 "<=" sugar for log:isImpliedBy
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+// lexer tokenizes Turtle/N3 source read from r. It is a deliberately
+// simplified scanner: it accepts the common, unambiguous core of the
+// Turtle 1.1 grammar (see parse.go's doc comment for the feature list)
+// rather than implementing every PN_CHARS production byte for byte.
+type lexer struct {
+	r    *bufio.Reader
+	line int
+}
+
+func newLexer(r io.Reader) *lexer {
+	return &lexer{r: bufio.NewReader(r), line: 1}
+}
+
+func (l *lexer) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("turtle: line %d: %s", l.line, fmt.Sprintf(format, args...))
+}
+
+func (l *lexer) readRune() (rune, error) {
+	r, _, err := l.r.ReadRune()
+	if err == nil && r == '\n' {
+		l.line++
+	}
+	return r, err
+}
+
+func (l *lexer) unreadRune() { l.r.UnreadRune() }
+
+func (l *lexer) peekRune() (rune, error) {
+	r, err := l.readRune()
+	if err == nil {
+		l.unreadRune()
+		if r == '\n' {
+			l.line--
+		}
+	}
+	return r, err
+}
+
+func isPNChar(r rune) bool {
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		return true
+	case r == '_' || r == '-':
+		return true
+	}
+	return false
+}
+
+func isWS(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\r' || r == '\n'
+}
+
+// skipInsignificant consumes whitespace and "#" comments between tokens.
+func (l *lexer) skipInsignificant() error {
+	for {
+		r, err := l.readRune()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch {
+		case isWS(r):
+			continue
+		case r == '#':
+			for {
+				r, err := l.readRune()
+				if err == io.EOF || r == '\n' {
+					break
+				}
+				if err != nil {
+					return err
+				}
+			}
+			continue
+		default:
+			l.unreadRune()
+			return nil
+		}
+	}
+}
+
+// next returns the next token in the stream, or a tokEOF token once the
+// input is exhausted.
+func (l *lexer) next() (token, error) {
+	if err := l.skipInsignificant(); err != nil {
+		return token{}, err
+	}
+	r, err := l.readRune()
+	if err == io.EOF {
+		return token{kind: tokEOF}, nil
+	}
+	if err != nil {
+		return token{}, err
+	}
+
+	switch r {
+	case '.':
+		// A "." immediately followed by a digit is a decimal starting
+		// with no integer part; otherwise it's the end-of-statement dot.
+		if nr, err := l.peekRune(); err == nil && nr >= '0' && nr <= '9' {
+			return l.lexNumber('.')
+		}
+		return token{kind: tokDot}, nil
+	case ';':
+		return token{kind: tokSemicolon}, nil
+	case ',':
+		return token{kind: tokComma}, nil
+	case '[':
+		return token{kind: tokOpenBracket}, nil
+	case ']':
+		return token{kind: tokCloseBracket}, nil
+	case '(':
+		return token{kind: tokOpenParen}, nil
+	case ')':
+		return token{kind: tokCloseParen}, nil
+	case '^':
+		nr, err := l.readRune()
+		if err != nil || nr != '^' {
+			return token{}, l.errorf("expected '^^', got a lone '^'")
+		}
+		return token{kind: tokDoubleCaret}, nil
+	case '@':
+		return l.lexAt()
+	case '<':
+		return l.lexIRIRefOrImpliedBy()
+	case '_':
+		return l.lexBlankNodeLabel()
+	case '"', '\'':
+		return l.lexString(r)
+	default:
+		switch {
+		case r == '+' || r == '-' || (r >= '0' && r <= '9'):
+			return l.lexNumber(r)
+		case isPNChar(r) || r == ':':
+			return l.lexNameOrKeyword(r)
+		}
+	}
+	return token{}, l.errorf("unexpected character %q", r)
+}
+
+func (l *lexer) lexIRIRefOrImpliedBy() (token, error) {
+	if nr, err := l.peekRune(); err == nil && nr == '=' {
+		l.readRune()
+		return token{kind: tokImpliedBy}, nil
+	}
+	var sb strings.Builder
+	for {
+		r, err := l.readRune()
+		if err != nil {
+			return token{}, l.errorf("unterminated IRIREF: %v", err)
+		}
+		if r == '>' {
+			break
+		}
+		if r == '\\' {
+			ur, err := l.readEscape(false)
+			if err != nil {
+				return token{}, err
+			}
+			sb.WriteRune(ur)
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	return token{kind: tokIRIRef, text: sb.String()}, nil
+}
+
+func (l *lexer) lexBlankNodeLabel() (token, error) {
+	nr, err := l.readRune()
+	if err != nil || nr != ':' {
+		return token{}, l.errorf("expected ':' after '_'")
+	}
+	var sb strings.Builder
+	for {
+		r, err := l.peekRune()
+		if err != nil || !(isPNChar(r) || r == '.') {
+			break
+		}
+		l.readRune()
+		sb.WriteRune(r)
+	}
+	return token{kind: tokBlankNodeLabel, text: strings.TrimRight(sb.String(), ".")}, nil
+}
+
+// lexName scans a run of PN_CHARS: the rest of a prefix after its first
+// rune, or the local part of a PNAME_LN after its colon was consumed.
+func (l *lexer) lexName() string {
+	var sb strings.Builder
+	for {
+		r, err := l.peekRune()
+		if err != nil || !isPNChar(r) {
+			break
+		}
+		l.readRune()
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+func (l *lexer) lexNameOrKeyword(first rune) (token, error) {
+	// A name starting with ':' is the empty-prefix form of PNAME_NS/
+	// PNAME_LN ("@prefix : <...> ." then ":bob"), so its local part
+	// begins immediately rather than after a colon found later.
+	if first == ':' {
+		local := l.lexName()
+		if local == "" {
+			return token{kind: tokPNameNS, text: ""}, nil
+		}
+		return token{kind: tokPNameLN, text: ":" + local}, nil
+	}
+
+	name := string(first) + l.lexName()
+
+	nr, err := l.peekRune()
+	if err == nil && nr == ':' {
+		l.readRune()
+		local := l.lexName()
+		if local == "" {
+			return token{kind: tokPNameNS, text: name}, nil
+		}
+		return token{kind: tokPNameLN, text: name + ":" + local}, nil
+	}
+
+	switch name {
+	case "a":
+		return token{kind: tokA}, nil
+	case "true", "false":
+		return token{kind: tokBoolean, text: name}, nil
+	case "PREFIX":
+		return token{kind: tokPrefixKeyword}, nil
+	case "BASE":
+		return token{kind: tokBaseKeyword}, nil
+	}
+	return token{}, l.errorf("unexpected bare name %q", name)
+}
+
+func (l *lexer) lexAt() (token, error) {
+	var sb strings.Builder
+	for {
+		r, err := l.peekRune()
+		if err != nil || !(isPNChar(r) || r == '-') {
+			break
+		}
+		l.readRune()
+		sb.WriteRune(r)
+	}
+	name := sb.String()
+	switch name {
+	case "prefix":
+		return token{kind: tokPrefixKeyword}, nil
+	case "base":
+		return token{kind: tokBaseKeyword}, nil
+	}
+	return token{kind: tokLangTag, text: name}, nil
+}
+
+func (l *lexer) lexString(quote rune) (token, error) {
+	long := false
+	if nr1, err := l.peekRune(); err == nil && nr1 == quote {
+		l.readRune()
+		if nr2, err := l.peekRune(); err == nil && nr2 == quote {
+			l.readRune()
+			long = true
+		} else {
+			// Empty short string: the two quotes just read were it.
+			return token{kind: tokString, text: ""}, nil
+		}
+	}
+
+	var sb strings.Builder
+	for {
+		r, err := l.readRune()
+		if err != nil {
+			return token{}, l.errorf("unterminated string literal: %v", err)
+		}
+		if r == '\\' {
+			ur, err := l.readEscape(true)
+			if err != nil {
+				return token{}, err
+			}
+			sb.WriteRune(ur)
+			continue
+		}
+		if r == quote {
+			if !long {
+				break
+			}
+			r2, err := l.peekRune()
+			if err == nil && r2 == quote {
+				l.readRune()
+				r3, err := l.peekRune()
+				if err == nil && r3 == quote {
+					l.readRune()
+					break
+				}
+				sb.WriteRune(quote)
+				sb.WriteRune(quote)
+				continue
+			}
+			sb.WriteRune(quote)
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	return token{kind: tokString, text: sb.String()}, nil
+}
+
+// readEscape consumes the character(s) after a backslash already read
+// from the stream. withEchar also accepts the ECHAR escapes valid inside
+// quoted strings (\t \b \n \r \f \" \' \\); IRIREFs only allow \uXXXX and
+// \UXXXXXXXX.
+func (l *lexer) readEscape(withEchar bool) (rune, error) {
+	r, err := l.readRune()
+	if err != nil {
+		return 0, l.errorf("unterminated escape sequence: %v", err)
+	}
+	if withEchar {
+		switch r {
+		case 't':
+			return '\t', nil
+		case 'b':
+			return '\b', nil
+		case 'n':
+			return '\n', nil
+		case 'r':
+			return '\r', nil
+		case 'f':
+			return '\f', nil
+		case '"':
+			return '"', nil
+		case '\'':
+			return '\'', nil
+		case '\\':
+			return '\\', nil
+		}
+	}
+	switch r {
+	case 'u':
+		return l.readHex(4)
+	case 'U':
+		return l.readHex(8)
+	}
+	return 0, l.errorf("invalid escape sequence \\%c", r)
+}
+
+func (l *lexer) readHex(n int) (rune, error) {
+	var v rune
+	for i := 0; i < n; i++ {
+		r, err := l.readRune()
+		if err != nil {
+			return 0, l.errorf("unterminated unicode escape: %v", err)
+		}
+		var d rune
+		switch {
+		case r >= '0' && r <= '9':
+			d = r - '0'
+		case r >= 'a' && r <= 'f':
+			d = r - 'a' + 10
+		case r >= 'A' && r <= 'F':
+			d = r - 'A' + 10
+		default:
+			return 0, l.errorf("invalid hex digit %q in unicode escape", r)
+		}
+		v = v<<4 | d
+	}
+	return v, nil
+}
+
+func (l *lexer) lexNumber(first rune) (token, error) {
+	var sb strings.Builder
+	sb.WriteRune(first)
+	isDouble, isDecimal := false, first == '.'
+
+	consumeDigits := func() {
+		for {
+			r, err := l.peekRune()
+			if err != nil || r < '0' || r > '9' {
+				return
+			}
+			l.readRune()
+			sb.WriteRune(r)
+		}
+	}
+	consumeDigits()
+
+	if r, err := l.peekRune(); err == nil && r == '.' && first != '.' {
+		if nr, err := l.peek2(); err == nil && nr >= '0' && nr <= '9' {
+			l.readRune()
+			sb.WriteRune('.')
+			isDecimal = true
+			consumeDigits()
+		}
+	}
+	if r, err := l.peekRune(); err == nil && (r == 'e' || r == 'E') {
+		l.readRune()
+		sb.WriteRune(r)
+		isDouble = true
+		isDecimal = false
+		if r, err := l.peekRune(); err == nil && (r == '+' || r == '-') {
+			l.readRune()
+			sb.WriteRune(r)
+		}
+		consumeDigits()
+	}
+
+	kind := tokInteger
+	if isDouble {
+		kind = tokDouble
+	} else if isDecimal {
+		kind = tokDecimal
+	}
+	return token{kind: kind, text: sb.String()}, nil
+}
+
+// peek2 peeks one rune past the current position, without consuming
+// either rune.
+func (l *lexer) peek2() (rune, error) {
+	r1, err := l.readRune()
+	if err != nil {
+		return 0, err
+	}
+	r2, err := l.peekRune()
+	l.unreadFirst(r1)
+	return r2, err
+}
+
+// unreadFirst pushes r1 back in front of the stream after a peek2 lookahead.
+func (l *lexer) unreadFirst(r1 rune) {
+	// bufio.Reader only supports a single UnreadRune, so splice r1 back
+	// onto the front of the buffered stream via a combined reader.
+	l.r = bufio.NewReader(io.MultiReader(strings.NewReader(string(r1)), l.r))
+}
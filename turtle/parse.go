@@ -0,0 +1,459 @@
+// Copyright 2014 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package turtle implements a reader for the Turtle 1.1 RDF syntax
+// (https://www.w3.org/TR/turtle/), reusing nquads.Term/Quad internally and
+// exposing the same Decoder-shaped interface as the nquads and jsonld
+// packages, so it drops into Cayley's existing loaders.
+//
+// The supported grammar covers: @prefix/@base and their SPARQL-style
+// PREFIX/BASE spellings, prefixed names, "a" as shorthand for rdf:type,
+// ";"-separated predicate-object lists and ","-separated object lists,
+// "[ ... ]" blank-node property lists, "( ... )" collections, the
+// integer/decimal/double/boolean literal shorthands, and single- and
+// triple-quoted strings. With Options.N3 set, the N3 "<=" token is
+// accepted as sugar for log:isImpliedBy.
+package turtle
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/google/cayley/graph"
+	"github.com/google/cayley/iri"
+	"github.com/google/cayley/nquads"
+)
+
+const (
+	rdfType        = "http://www.w3.org/1999/02/22-rdf-syntax-ns#type"
+	rdfFirst       = "http://www.w3.org/1999/02/22-rdf-syntax-ns#first"
+	rdfRest        = "http://www.w3.org/1999/02/22-rdf-syntax-ns#rest"
+	rdfNil         = "http://www.w3.org/1999/02/22-rdf-syntax-ns#nil"
+	logIsImpliedBy = "http://www.w3.org/2000/10/swap/log#isImpliedBy"
+
+	xsdInteger = "http://www.w3.org/2001/XMLSchema#integer"
+	xsdDecimal = "http://www.w3.org/2001/XMLSchema#decimal"
+	xsdDouble  = "http://www.w3.org/2001/XMLSchema#double"
+	xsdBoolean = "http://www.w3.org/2001/XMLSchema#boolean"
+)
+
+// Options controls how a parser resolves prefixes/IRIs and whether it
+// accepts N3 syntax sugar on top of plain Turtle.
+type Options struct {
+	// Base is the initial base IRI used to resolve relative references,
+	// before any @base/BASE directive in the document overrides it.
+	Base string
+	// N3 enables the "<=" token as sugar for log:isImpliedBy.
+	N3 bool
+}
+
+type parser struct {
+	lex        *lexer
+	tok        token
+	pending    error
+	prefixes   map[string]string
+	base       string
+	n3         bool
+	blankCount int
+}
+
+func newParser(r io.Reader, opts Options) *parser {
+	p := &parser{
+		lex:      newLexer(r),
+		prefixes: map[string]string{},
+		base:     opts.Base,
+		n3:       opts.N3,
+	}
+	p.pending = p.advance()
+	return p
+}
+
+func (p *parser) advance() error {
+	t, err := p.lex.next()
+	if err != nil {
+		return err
+	}
+	p.tok = t
+	return nil
+}
+
+func (p *parser) freshBlank() nquads.Term {
+	n := p.blankCount
+	p.blankCount++
+	return nquads.BlankNode(fmt.Sprintf("turtle%d", n))
+}
+
+func (p *parser) resolve(ref string) string {
+	if p.base == "" {
+		return ref
+	}
+	resolved, err := iri.Resolve(p.base, ref)
+	if err != nil {
+		return ref
+	}
+	return resolved
+}
+
+func (p *parser) resolvePrefixed(name string) (string, error) {
+	prefix, local := name, ""
+	if i := indexByte(name, ':'); i >= 0 {
+		prefix, local = name[:i], name[i+1:]
+	}
+	ns, ok := p.prefixes[prefix]
+	if !ok {
+		return "", p.errorf("undefined prefix %q", prefix)
+	}
+	return ns + local, nil
+}
+
+func indexByte(s string, b byte) int {
+	for i := 0; i < len(s); i++ {
+		if s[i] == b {
+			return i
+		}
+	}
+	return -1
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("turtle: %s", fmt.Sprintf(format, args...))
+}
+
+// nextStatement consumes directives silently and returns the quads
+// produced by the next triples statement. It returns io.EOF once the
+// input is exhausted.
+func (p *parser) nextStatement() ([]*nquads.Quad, error) {
+	if p.pending != nil {
+		err := p.pending
+		p.pending = nil
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+		return nil, err
+	}
+	for {
+		switch p.tok.kind {
+		case tokEOF:
+			return nil, io.EOF
+		case tokPrefixKeyword:
+			if err := p.parsePrefixDirective(); err != nil {
+				return nil, err
+			}
+		case tokBaseKeyword:
+			if err := p.parseBaseDirective(); err != nil {
+				return nil, err
+			}
+		default:
+			return p.parseTriples()
+		}
+	}
+}
+
+func (p *parser) parsePrefixDirective() error {
+	if err := p.advance(); err != nil {
+		return err
+	}
+	if p.tok.kind != tokPNameNS {
+		return p.errorf("expected a prefix name after @prefix/PREFIX")
+	}
+	name := p.tok.text
+	if err := p.advance(); err != nil {
+		return err
+	}
+	if p.tok.kind != tokIRIRef {
+		return p.errorf("expected an IRI after prefix name %q", name)
+	}
+	p.prefixes[name] = p.resolve(p.tok.text)
+	if err := p.advance(); err != nil {
+		return err
+	}
+	if p.tok.kind == tokDot {
+		return p.advance()
+	}
+	return nil
+}
+
+func (p *parser) parseBaseDirective() error {
+	if err := p.advance(); err != nil {
+		return err
+	}
+	if p.tok.kind != tokIRIRef {
+		return p.errorf("expected an IRI after @base/BASE")
+	}
+	p.base = p.resolve(p.tok.text)
+	if err := p.advance(); err != nil {
+		return err
+	}
+	if p.tok.kind == tokDot {
+		return p.advance()
+	}
+	return nil
+}
+
+func (p *parser) parseTriples() ([]*nquads.Quad, error) {
+	subject, quads, fromPropertyList, err := p.parseSubject()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokDot {
+		qs, err := p.parsePredicateObjectList(subject)
+		if err != nil {
+			return nil, err
+		}
+		quads = append(quads, qs...)
+	} else if !fromPropertyList {
+		return nil, p.errorf("expected a predicate, got end of statement")
+	}
+	if p.tok.kind != tokDot {
+		return nil, p.errorf("expected '.' to end a statement")
+	}
+	return quads, p.advance()
+}
+
+// parseSubject parses a Turtle subject position: an IRI, a blank node
+// label, a "[ ... ]" blank-node property list, or a "( ... )" collection.
+// fromPropertyList reports whether a predicateObjectList is optional
+// because subject is itself a property list already carrying triples.
+func (p *parser) parseSubject() (subject nquads.Term, quads []*nquads.Quad, fromPropertyList bool, err error) {
+	switch p.tok.kind {
+	case tokIRIRef:
+		t := nquads.IRI(p.resolve(p.tok.text))
+		return t, nil, false, p.advance()
+	case tokPNameLN, tokPNameNS:
+		iriStr, err := p.resolvePrefixed(p.tok.text)
+		if err != nil {
+			return nil, nil, false, err
+		}
+		return nquads.IRI(iriStr), nil, false, p.advance()
+	case tokBlankNodeLabel:
+		t := nquads.BlankNode(p.tok.text)
+		return t, nil, false, p.advance()
+	case tokOpenBracket:
+		t, qs, err := p.parseBlankNodePropertyList()
+		return t, qs, true, err
+	case tokOpenParen:
+		t, qs, err := p.parseCollection()
+		return t, qs, false, err
+	}
+	return nil, nil, false, p.errorf("unexpected token in subject position")
+}
+
+func (p *parser) parsePredicateObjectList(subject nquads.Term) ([]*nquads.Quad, error) {
+	var quads []*nquads.Quad
+	for {
+		pred, err := p.parseVerb()
+		if err != nil {
+			return nil, err
+		}
+		qs, err := p.parseObjectList(subject, pred)
+		if err != nil {
+			return nil, err
+		}
+		quads = append(quads, qs...)
+
+		if p.tok.kind != tokSemicolon {
+			break
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		// Trailing ";" with no further predicateObjectList is legal.
+		if p.tok.kind == tokDot || p.tok.kind == tokCloseBracket {
+			break
+		}
+	}
+	return quads, nil
+}
+
+func (p *parser) parseVerb() (nquads.Term, error) {
+	switch p.tok.kind {
+	case tokA:
+		return nquads.IRI(rdfType), p.advance()
+	case tokImpliedBy:
+		if !p.n3 {
+			return nil, p.errorf(`"<=" is only recognized in N3 mode`)
+		}
+		return nquads.IRI(logIsImpliedBy), p.advance()
+	case tokIRIRef:
+		t := nquads.IRI(p.resolve(p.tok.text))
+		return t, p.advance()
+	case tokPNameLN, tokPNameNS:
+		iriStr, err := p.resolvePrefixed(p.tok.text)
+		if err != nil {
+			return nil, err
+		}
+		return nquads.IRI(iriStr), p.advance()
+	}
+	return nil, p.errorf("unexpected token in predicate position")
+}
+
+func (p *parser) parseObjectList(subject, predicate nquads.Term) ([]*nquads.Quad, error) {
+	var quads []*nquads.Quad
+	for {
+		obj, qs, err := p.parseObject()
+		if err != nil {
+			return nil, err
+		}
+		quads = append(quads, qs...)
+		quads = append(quads, &nquads.Quad{Subject: subject, Predicate: predicate, Object: obj})
+
+		if p.tok.kind != tokComma {
+			break
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+	return quads, nil
+}
+
+func (p *parser) parseObject() (nquads.Term, []*nquads.Quad, error) {
+	switch p.tok.kind {
+	case tokIRIRef:
+		t := nquads.IRI(p.resolve(p.tok.text))
+		return t, nil, p.advance()
+	case tokPNameLN, tokPNameNS:
+		iriStr, err := p.resolvePrefixed(p.tok.text)
+		if err != nil {
+			return nil, nil, err
+		}
+		return nquads.IRI(iriStr), nil, p.advance()
+	case tokBlankNodeLabel:
+		t := nquads.BlankNode(p.tok.text)
+		return t, nil, p.advance()
+	case tokOpenBracket:
+		return p.parseBlankNodePropertyList()
+	case tokOpenParen:
+		return p.parseCollection()
+	case tokString:
+		return p.parseLiteral()
+	case tokInteger, tokDecimal, tokDouble, tokBoolean:
+		return p.parseNumericLiteral()
+	}
+	return nil, nil, p.errorf("unexpected token in object position")
+}
+
+func (p *parser) parseBlankNodePropertyList() (nquads.Term, []*nquads.Quad, error) {
+	if err := p.advance(); err != nil { // consume '['
+		return nil, nil, err
+	}
+	bnode := p.freshBlank()
+	if p.tok.kind == tokCloseBracket {
+		return bnode, nil, p.advance()
+	}
+	quads, err := p.parsePredicateObjectList(bnode)
+	if err != nil {
+		return nil, nil, err
+	}
+	if p.tok.kind != tokCloseBracket {
+		return nil, nil, p.errorf("expected ']' to close a blank node property list")
+	}
+	return bnode, quads, p.advance()
+}
+
+func (p *parser) parseCollection() (nquads.Term, []*nquads.Quad, error) {
+	if err := p.advance(); err != nil { // consume '('
+		return nil, nil, err
+	}
+	var items []nquads.Term
+	var quads []*nquads.Quad
+	for p.tok.kind != tokCloseParen {
+		item, qs, err := p.parseObject()
+		if err != nil {
+			return nil, nil, err
+		}
+		items = append(items, item)
+		quads = append(quads, qs...)
+	}
+	if err := p.advance(); err != nil { // consume ')'
+		return nil, nil, err
+	}
+
+	if len(items) == 0 {
+		return nquads.IRI(rdfNil), quads, nil
+	}
+	var head, prev nquads.Term
+	for i, item := range items {
+		cell := p.freshBlank()
+		if i == 0 {
+			head = cell
+		} else {
+			quads = append(quads, &nquads.Quad{Subject: prev, Predicate: nquads.IRI(rdfRest), Object: cell})
+		}
+		quads = append(quads, &nquads.Quad{Subject: cell, Predicate: nquads.IRI(rdfFirst), Object: item})
+		prev = cell
+	}
+	quads = append(quads, &nquads.Quad{Subject: prev, Predicate: nquads.IRI(rdfRest), Object: nquads.IRI(rdfNil)})
+	return head, quads, nil
+}
+
+func (p *parser) parseLiteral() (nquads.Term, []*nquads.Quad, error) {
+	lit := nquads.Literal{Value: p.tok.text}
+	if err := p.advance(); err != nil {
+		return nil, nil, err
+	}
+	switch p.tok.kind {
+	case tokLangTag:
+		lit.Lang = p.tok.text
+		return lit, nil, p.advance()
+	case tokDoubleCaret:
+		if err := p.advance(); err != nil {
+			return nil, nil, err
+		}
+		dt, err := p.parseDatatypeIRI()
+		if err != nil {
+			return nil, nil, err
+		}
+		lit.Datatype = nquads.IRI(dt)
+		return lit, nil, nil
+	}
+	return lit, nil, nil
+}
+
+func (p *parser) parseDatatypeIRI() (string, error) {
+	switch p.tok.kind {
+	case tokIRIRef:
+		s := p.resolve(p.tok.text)
+		return s, p.advance()
+	case tokPNameLN, tokPNameNS:
+		s, err := p.resolvePrefixed(p.tok.text)
+		if err != nil {
+			return "", err
+		}
+		return s, p.advance()
+	}
+	return "", p.errorf("expected a datatype IRI after '^^'")
+}
+
+func (p *parser) parseNumericLiteral() (nquads.Term, []*nquads.Quad, error) {
+	lit := nquads.Literal{Value: p.tok.text}
+	switch p.tok.kind {
+	case tokInteger:
+		lit.Datatype = xsdInteger
+	case tokDecimal:
+		lit.Datatype = xsdDecimal
+	case tokDouble:
+		lit.Datatype = xsdDouble
+	case tokBoolean:
+		lit.Datatype = xsdBoolean
+	}
+	return lit, nil, p.advance()
+}
+
+// ParseAll parses the entire contents of r and returns the graph.Triple
+// values it describes.
+func ParseAll(r io.Reader, opts Options) ([]*graph.Triple, error) {
+	d := NewDecoder(r, opts)
+	return d.DecodeAll()
+}
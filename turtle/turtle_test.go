@@ -0,0 +1,103 @@
+// Copyright 2014 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package turtle
+
+import (
+	"sort"
+	"strings"
+	"testing"
+)
+
+func parseStrings(t *testing.T, src string, opts Options) []string {
+	t.Helper()
+	ts, err := ParseAll(strings.NewReader(src), opts)
+	if err != nil {
+		t.Fatalf("ParseAll: %v", err)
+	}
+	var out []string
+	for _, tr := range ts {
+		out = append(out, tr.String())
+	}
+	sort.Strings(out)
+	return out
+}
+
+func TestParsePrefixAndVerbShorthand(t *testing.T) {
+	src := `@prefix foaf: <http://xmlns.com/foaf/0.1/> .
+<http://example.org/bob> a foaf:Person ;
+	foaf:name "Bob" .`
+	got := parseStrings(t, src, Options{})
+	want := []string{
+		`<http://example.org/bob> -- <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> -> <http://xmlns.com/foaf/0.1/Person>`,
+		`<http://example.org/bob> -- <http://xmlns.com/foaf/0.1/name> -> "Bob"`,
+	}
+	sort.Strings(want)
+	if len(got) != len(want) {
+		t.Fatalf("got %v want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("got %v want %v", got, want)
+		}
+	}
+}
+
+func TestParseBaseAndObjectList(t *testing.T) {
+	src := `@base <http://example.org/> .
+<bob> <http://example.org/knows> <alice>, <carol> .`
+	got := parseStrings(t, src, Options{})
+	if len(got) != 2 {
+		t.Fatalf("expected 2 triples from an object list, got %d: %v", len(got), got)
+	}
+}
+
+func TestParseBlankNodePropertyList(t *testing.T) {
+	src := `<http://example.org/bob> <http://example.org/knows> [ <http://example.org/name> "Alice" ] .`
+	got := parseStrings(t, src, Options{})
+	if len(got) != 2 {
+		t.Fatalf("expected 2 triples (the link plus the nested property), got %d: %v", len(got), got)
+	}
+}
+
+func TestParseCollection(t *testing.T) {
+	src := `<http://example.org/bob> <http://example.org/nums> ( 1 2 ) .`
+	got := parseStrings(t, src, Options{})
+	// 1 link to the list head + 2 items * (rdf:first + rdf:rest) = 5.
+	if len(got) != 5 {
+		t.Fatalf("expected 5 triples for a 2-element collection, got %d: %v", len(got), got)
+	}
+}
+
+func TestParseDefaultPrefix(t *testing.T) {
+	src := `@prefix : <http://example.org/> .
+:bob :knows :alice .`
+	got := parseStrings(t, src, Options{})
+	want := []string{`<http://example.org/bob> -- <http://example.org/knows> -> <http://example.org/alice>`}
+	if len(got) != 1 || got[0] != want[0] {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
+func TestParseN3ImpliedBy(t *testing.T) {
+	src := `<http://example.org/a> <= <http://example.org/b> .`
+	if _, err := ParseAll(strings.NewReader(src), Options{}); err == nil {
+		t.Fatalf("expected \"<=\" to be rejected outside N3 mode")
+	}
+	got := parseStrings(t, src, Options{N3: true})
+	want := `<http://example.org/a> -- <http://www.w3.org/2000/10/swap/log#isImpliedBy> -> <http://example.org/b>`
+	if len(got) != 1 || got[0] != want {
+		t.Errorf("got %v want [%s]", got, want)
+	}
+}